@@ -9,12 +9,18 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
 	"golang.org/x/crypto/openpgp/packet"
 )
 
+// gpgPassphraseEnvVar is the fallback environment variable consulted for a
+// GPG secret key passphrase when WithGPGPassphrase is not used.
+const gpgPassphraseEnvVar = "EASYCFG_GPG_PASSPHRASE"
+
 // GnuPGHome points to the default location on the filesystem for the GnuPG keyrings.
 // If using a non-standard location, this can be set at compile time via linker flags.
 var GnuPGHome = "/root/.gnupg"
@@ -23,9 +29,39 @@ var standard *pflag.FlagSet
 var encrypted *pflag.FlagSet
 var gpgEntities openpgp.EntityList
 var once sync.Once
+var configFilePath string
+
+// encryptedMu guards encryptedValues, as well as viper reads that might race
+// with a config reload triggered by Watch.
+var encryptedMu sync.RWMutex
+var encryptedValues = map[string]string{}
+
+// gpgPassphrase, when set via WithGPGPassphrase, unlocks passphrase-protected
+// GPG secret keys. trustedSigners, when set via WithTrustedSigners, restricts
+// decryption to messages signed by one of the listed key fingerprints.
+var gpgPassphrase func() ([]byte, error)
+var trustedSigners []string
+
+// structBindings holds one entry per RegisterStruct field, so InitConfig can
+// assign each field from viper by its `easycfg` tag name once flags are
+// parsed and config is read. We deliberately don't use viper.Unmarshal here:
+// mapstructure (which it delegates to) matches by its own `mapstructure` tag
+// or a case-insensitive field name, never by our `easycfg` tag, so any field
+// whose tag name doesn't case-fold to the Go field name would silently never
+// be populated.
+var structBindings []fieldBinding
 
 type ProgramOpts func()
 
+// WithConfigFile points InitConfig at an explicit configuration file,
+// bypassing the default search path. The file's extension determines how
+// viper parses it (JSON, YAML, TOML, HCL, or envfile).
+func WithConfigFile(path string) ProgramOpts {
+	return func() {
+		configFilePath = path
+	}
+}
+
 // WithStringVar is a simple wrapper around pflag.StringVar. It allows this package to present its unique way of setting
 // program arguments to the user while still leaning on spf13/pflag for flag setting logic.
 func WithStringVar(opt *string, name string, defaultValue string, usage string) ProgramOpts {
@@ -50,15 +86,31 @@ func WithBoolVar(opt *bool, name string, defaultValue bool, usage string) Progra
 	}
 }
 
-// WithGPGEncryptedValueFromFile is a simple wrapper around pflag.StringVar. It allows this package to present its
-// unique way of setting program arguments to the user while still leaning on spf13/pflag for flag setting logic.
-// This function uses a separate pflag.FlagSet that is used to identify encrypted values.
-func WithGPGEncryptedValueFromFile(opt *string, name string, defaultValue string, usage string) ProgramOpts {
+// WithGPGPassphrase supplies a callback used to unlock a passphrase-protected
+// GPG secret key when decrypting encrypted values. If unset, InitConfig falls
+// back to the EASYCFG_GPG_PASSPHRASE environment variable, then to an empty
+// passphrase.
+func WithGPGPassphrase(prompt func() ([]byte, error)) ProgramOpts {
 	return func() {
-		encrypted.StringVar(opt, name, defaultValue, usage)
+		gpgPassphrase = prompt
 	}
 }
 
+// WithTrustedSigners restricts decryption of GPG-backed values to messages
+// signed by one of the given key fingerprints. When set, gpgDecodeFromFile
+// returns an error for unsigned, unverifiable, or untrusted messages.
+func WithTrustedSigners(fingerprints ...string) ProgramOpts {
+	return func() {
+		trustedSigners = fingerprints
+	}
+}
+
+// WithGPGEncryptedValueFromFile is a thin wrapper around WithSecretVar using
+// the "gpg-file" SecretProvider, kept for backwards compatibility.
+func WithGPGEncryptedValueFromFile(opt *string, name string, defaultValue string, usage string) ProgramOpts {
+	return WithSecretVar(opt, name, defaultValue, usage, gpgFileProviderName)
+}
+
 // InitConfig initializes the program's configuration.
 func InitConfig(serviceName string, opts ...ProgramOpts) error {
 
@@ -66,6 +118,58 @@ func InitConfig(serviceName string, opts ...ProgramOpts) error {
 		return fmt.Errorf("required parameter not set")
 	}
 
+	ensureInitialized()
+
+	for _, opt := range opts {
+		opt()
+	}
+
+	viper.SetEnvPrefix(serviceName)
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+
+	standard.VisitAll(func(flag *pflag.Flag) {
+		bindEnv(flag)
+	})
+	encrypted.VisitAll(func(flag *pflag.Flag) {
+		bindEnv(flag)
+	})
+
+	pflag.CommandLine.AddFlagSet(standard)
+	pflag.CommandLine.AddFlagSet(encrypted)
+	pflag.Parse()
+
+	if err := readConfigFile(serviceName); err != nil {
+		return err
+	}
+
+	if err := readRemoteProviders(); err != nil {
+		return err
+	}
+
+	// bind every flag to viper so the precedence chain becomes
+	// flag > env > config file > default.
+	standard.VisitAll(func(flag *pflag.Flag) {
+		_ = viper.BindPFlag(flag.Name, flag)
+	})
+	encrypted.VisitAll(func(flag *pflag.Flag) {
+		_ = viper.BindPFlag(flag.Name, flag)
+	})
+
+	if err := decryptEncryptedValues(); err != nil {
+		return err
+	}
+
+	if err := applyStructBindings(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ensureInitialized sets up the standard/encrypted FlagSets and loads the
+// local GnuPG keyring exactly once, however many times InitConfig or
+// RegisterStruct are called.
+func ensureInitialized() {
 	once.Do(func() {
 		standard = pflag.NewFlagSet("standard", pflag.ExitOnError)
 		encrypted = pflag.NewFlagSet("encrypted", pflag.ExitOnError)
@@ -84,34 +188,175 @@ func InitConfig(serviceName string, opts ...ProgramOpts) error {
 
 		gpgEntities = append(se, pe...)
 	})
+}
 
-	for _, opt := range opts {
-		opt()
-	}
-
-	// todo: Need to bind options to settings file entries
-	standard.VisitAll(func(flag *pflag.Flag) {
-		bindEnv(flag)
-	})
-	encrypted.VisitAll(func(flag *pflag.Flag) {
-		bindEnv(flag)
-	})
-
-	pflag.Parse()
+// decryptEncryptedValues decodes every GPG-backed flag in the encrypted
+// FlagSet and stores the plaintext in both viper and encryptedValues. It is
+// called during InitConfig and again on every config change observed by
+// Watch, so secrets rotated on disk get picked up without a restart. It
+// returns the first error encountered (e.g. an untrusted signer rejected by
+// verifySigner, or an unimplemented/misconfigured SecretProvider), leaving
+// that flag's value untouched rather than silently caching an empty string.
+func decryptEncryptedValues() error {
+	encryptedMu.Lock()
+	defer encryptedMu.Unlock()
+	return decryptEncryptedValuesLocked()
+}
 
-	viper.SetEnvPrefix(serviceName)
-	// todo: handle GPG encrypted information and store unencrypted values...
-	// variables pointing to gpg encrypted assets are in the encrypted FlagSet.
+// decryptEncryptedValuesLocked is decryptEncryptedValues' body, factored out
+// so reloadConfigFile can run it inside the same critical section as the
+// viper.ReadInConfig call that precedes it, rather than taking encryptedMu
+// twice with an unlocked (and therefore racy) gap in between.
+func decryptEncryptedValuesLocked() error {
+	var firstErr error
 	encrypted.VisitAll(func(flag *pflag.Flag) {
 		name := flag.Name
-		value, _ := gpgDecodeFromFile(flag.Value.String())
+		provider := secretProviderFor(name)
+		value, err := provider.Decrypt(flag.Value.String())
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("easycfg: decrypting %q: %w", name, err)
+			}
+			return
+		}
+		encryptedValues[name] = value
 		viper.Set(name, value)
 	})
+	return firstErr
+}
+
+// GetEncryptedValue returns the decrypted plaintext last cached for an
+// encrypted flag registered via WithSecretVar/WithGPGEncryptedValueFromFile,
+// guarding against readers observing torn state while Watch is reloading the
+// configuration.
+func GetEncryptedValue(name string) (string, bool) {
+	encryptedMu.RLock()
+	defer encryptedMu.RUnlock()
+	value, ok := encryptedValues[name]
+	return value, ok
+}
+
+// Watch enables live reload of the configuration file, and, if any remote
+// providers were registered via AddRemoteProvider/WithEncryptedRemoteProvider,
+// polls them on remoteWatchInterval. Encrypted values are re-decrypted on
+// every reload, so secrets rotated in the files referenced by
+// WithGPGEncryptedValueFromFile are picked up on the fly. onChange is invoked
+// after every reload attempt with any error encountered (an untrusted
+// signer, a bad decrypt, or a remote fetch failure) so callers can detect and
+// react to it rather than have it silently swallowed.
+//
+// This deliberately does not delegate to viper.WatchConfig: its internal
+// fsnotify watcher calls v.ReadInConfig itself, before invoking our
+// OnConfigChange callback, entirely outside any lock we can take - so a
+// concurrent Get/GetString/GetEncryptedValue call can observe viper
+// mid-reload (torn reads, or a fatal concurrent map read/write under the
+// right timing). Watching the file ourselves lets reloadConfigFile hold
+// encryptedMu for the full reload, not just around the decrypt step.
+func Watch(onChange func(event fsnotify.Event, err error)) {
+	if configFile := viper.ConfigFileUsed(); configFile != "" {
+		go watchConfigFile(configFile, onChange)
+	}
+
+	if len(remoteProviders) > 0 {
+		go watchRemoteProviders(onChange)
+	}
+}
+
+// watchConfigFile watches configFile's directory for changes (the file
+// itself may be replaced wholesale, e.g. by an editor's atomic rename-based
+// save, so the directory - not the file - is what must be watched) and runs
+// reloadConfigFile for every write/create event that targets it, for the
+// lifetime of the program.
+func watchConfigFile(configFile string, onChange func(event fsnotify.Event, err error)) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(configFile)); err != nil {
+		return
+	}
+
+	for event := range watcher.Events {
+		if filepath.Clean(event.Name) != filepath.Clean(configFile) {
+			continue
+		}
+		if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+			continue
+		}
+
+		err := reloadConfigFile()
+		if onChange != nil {
+			onChange(event, err)
+		}
+	}
+}
+
+// reloadConfigFile re-reads the config file and re-decrypts encrypted values
+// as a single critical section, so Get/GetString/GetEncryptedValue never
+// observe viper mid-reload.
+func reloadConfigFile() error {
+	encryptedMu.Lock()
+	defer encryptedMu.Unlock()
+
+	if err := viper.ReadInConfig(); err != nil {
+		return err
+	}
+	return decryptEncryptedValuesLocked()
+}
+
+// Get is a thread-safe wrapper around viper.Get, guarding against readers
+// observing torn state while Watch is reloading the configuration.
+func Get(key string) interface{} {
+	encryptedMu.RLock()
+	defer encryptedMu.RUnlock()
+	return viper.Get(key)
+}
+
+// GetString is a thread-safe wrapper around viper.GetString, guarding against
+// readers observing torn state while Watch is reloading the configuration.
+func GetString(key string) string {
+	encryptedMu.RLock()
+	defer encryptedMu.RUnlock()
+	return viper.GetString(key)
+}
+
+// readConfigFile loads layered configuration from a JSON/YAML/TOML/HCL/envfile
+// via viper. If WithConfigFile was used, that path is read directly; otherwise
+// the default search path (/etc/<serviceName>/, $HOME/.<serviceName>/, and the
+// current directory) is searched for a file named after serviceName. A missing
+// config file is not an error, since flags, env vars, and defaults may be
+// sufficient on their own.
+func readConfigFile(serviceName string) error {
+	if configFilePath != "" {
+		if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
+			return nil
+		}
+		viper.SetConfigFile(configFilePath)
+	} else {
+		viper.SetConfigName(serviceName)
+		viper.AddConfigPath(filepath.Join("/etc", serviceName))
+		if home, err := os.UserHomeDir(); err == nil {
+			viper.AddConfigPath(filepath.Join(home, "."+serviceName))
+		}
+		viper.AddConfigPath(".")
+	}
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return nil
+		}
+		return err
+	}
 
 	return nil
 }
 
-// gpgDecodeFromFile decodes the encrypted value from the file at the file path provided.
+// gpgDecodeFromFile decodes the encrypted value from the file at the file path
+// provided. It accepts both binary and ASCII-armored OpenPGP messages, and,
+// when WithTrustedSigners has been used, verifies that the message is signed
+// by a trusted key.
 func gpgDecodeFromFile(fname string) (string, error) {
 	f, err := os.Open(fname)
 	if err != nil {
@@ -119,9 +364,36 @@ func gpgDecodeFromFile(fname string) (string, error) {
 	}
 	defer f.Close()
 
-	msg, err := openpgp.ReadMessage(f, gpgEntities, func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
-		return []byte{}, nil
-	}, &packet.Config{})
+	var r io.Reader = f
+	if block, armorErr := armor.Decode(f); armorErr == nil {
+		r = block.Body
+	} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	return gpgDecode(r)
+}
+
+// gpgDecodeBytes decodes an encrypted value read from ciphertext bytes
+// (e.g. a blob fetched from a remote config store) rather than a local file.
+// It accepts both binary and ASCII-armored OpenPGP messages, and, when
+// WithTrustedSigners has been used, verifies that the message is signed by a
+// trusted key.
+func gpgDecodeBytes(ciphertext []byte) (string, error) {
+	r := io.Reader(bytes.NewReader(ciphertext))
+	if block, err := armor.Decode(r); err == nil {
+		r = block.Body
+	} else {
+		r = bytes.NewReader(ciphertext)
+	}
+
+	return gpgDecode(r)
+}
+
+// gpgDecode reads and decrypts a single OpenPGP message from r, applying the
+// configured passphrase prompt and trusted-signer verification.
+func gpgDecode(r io.Reader) (string, error) {
+	msg, err := openpgp.ReadMessage(r, gpgEntities, gpgPromptFunc, &packet.Config{})
 	if err != nil {
 		return "", err
 	}
@@ -130,14 +402,67 @@ func gpgDecodeFromFile(fname string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+
+	if err := verifySigner(msg); err != nil {
+		return "", err
+	}
+
 	data = bytes.TrimSuffix(data, []byte("\n"))
 	return string(data), nil
 }
 
-// bindEnv binds the defined flags to properly formatted environment.
+// gpgPromptFunc supplies the passphrase used to unlock a passphrase-protected
+// GPG secret key, consulting WithGPGPassphrase, then EASYCFG_GPG_PASSPHRASE,
+// then falling back to no passphrase.
+func gpgPromptFunc(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+	pass := []byte{}
+	if gpgPassphrase != nil {
+		p, err := gpgPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		pass = p
+	} else if env, ok := os.LookupEnv(gpgPassphraseEnvVar); ok {
+		pass = []byte(env)
+	}
+	for _, k := range keys {
+		if k.PrivateKey != nil && k.PrivateKey.Encrypted && k.PrivateKey.Decrypt(pass) == nil {
+			return nil, nil
+		}
+	}
+	if symmetric {
+		return pass, nil
+	}
+	return nil, fmt.Errorf("gpg passphrase does not unlock any secret key")
+}
+
+// verifySigner checks msg against trustedSigners, when set via
+// WithTrustedSigners. It is a no-op if no trusted signers were configured.
+func verifySigner(msg *openpgp.MessageDetails) error {
+	if len(trustedSigners) == 0 {
+		return nil
+	}
+	if msg.SignatureError != nil {
+		return fmt.Errorf("gpg signature verification failed: %w", msg.SignatureError)
+	}
+	if msg.SignedBy == nil {
+		return fmt.Errorf("gpg message is not signed")
+	}
+
+	fingerprint := fmt.Sprintf("%X", msg.SignedBy.PublicKey.Fingerprint)
+	for _, trusted := range trustedSigners {
+		if strings.EqualFold(trusted, fingerprint) {
+			return nil
+		}
+	}
+	return fmt.Errorf("gpg message signed by untrusted key %s", fingerprint)
+}
+
+// bindEnv binds the defined flag to its properly formatted environment
+// variable. Using the 1-arg form of viper.BindEnv lets viper derive the env
+// name itself (SetEnvPrefix + uppercasing + the configured
+// SetEnvKeyReplacer), instead of hand-building a name that bypasses the
+// per-service prefix viper.SetEnvPrefix sets up in InitConfig.
 func bindEnv(flag *pflag.Flag) {
-	flagName := flag.Name
-	envName := strings.ReplaceAll(flagName, "-", "_")
-	envName = strings.ToUpper(flagName)
-	_ = viper.BindEnv(flagName, envName)
+	_ = viper.BindEnv(flag.Name)
 }
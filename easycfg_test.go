@@ -0,0 +1,70 @@
+package easycfg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func keyWithFingerprint(b byte) *openpgp.Key {
+	pub := &packet.PublicKey{}
+	pub.Fingerprint[19] = b
+	return &openpgp.Key{PublicKey: pub}
+}
+
+func fingerprintString(b byte) string {
+	var fp [20]byte
+	fp[19] = b
+	return fmt.Sprintf("%X", fp)
+}
+
+func TestVerifySignerNoTrustedSignersConfigured(t *testing.T) {
+	trustedSigners = nil
+	defer func() { trustedSigners = nil }()
+
+	if err := verifySigner(&openpgp.MessageDetails{}); err != nil {
+		t.Fatalf("verifySigner with no trusted signers configured should be a no-op, got %v", err)
+	}
+}
+
+func TestVerifySignerRejectsSignatureError(t *testing.T) {
+	trustedSigners = []string{fingerprintString(0xAB)}
+	defer func() { trustedSigners = nil }()
+
+	msg := &openpgp.MessageDetails{SignatureError: errors.New("boom")}
+	if err := verifySigner(msg); err == nil {
+		t.Fatal("expected an error for a failed signature check, got nil")
+	}
+}
+
+func TestVerifySignerRejectsUnsignedMessage(t *testing.T) {
+	trustedSigners = []string{fingerprintString(0xAB)}
+	defer func() { trustedSigners = nil }()
+
+	if err := verifySigner(&openpgp.MessageDetails{}); err == nil {
+		t.Fatal("expected an error for an unsigned message, got nil")
+	}
+}
+
+func TestVerifySignerRejectsUntrustedSigner(t *testing.T) {
+	trustedSigners = []string{fingerprintString(0xAB)}
+	defer func() { trustedSigners = nil }()
+
+	msg := &openpgp.MessageDetails{SignedBy: keyWithFingerprint(0xCD)}
+	if err := verifySigner(msg); err == nil {
+		t.Fatal("expected an error for an untrusted signer, got nil")
+	}
+}
+
+func TestVerifySignerAcceptsTrustedSigner(t *testing.T) {
+	trustedSigners = []string{fingerprintString(0xAB)}
+	defer func() { trustedSigners = nil }()
+
+	msg := &openpgp.MessageDetails{SignedBy: keyWithFingerprint(0xAB)}
+	if err := verifySigner(msg); err != nil {
+		t.Fatalf("expected a trusted signer to be accepted, got %v", err)
+	}
+}
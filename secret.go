@@ -0,0 +1,133 @@
+package easycfg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// gpgFileProviderName is the name the built-in GPG-file SecretProvider is
+// registered under.
+const gpgFileProviderName = "gpg-file"
+
+// symmetricPassphraseEnvVar is consulted for the passphrase used by the
+// "symmetric" SecretProvider.
+const symmetricPassphraseEnvVar = "EASYCFG_SYMMETRIC_PASSPHRASE"
+
+// SecretProvider resolves a secret reference, such as a file path, KMS key
+// ID, or Vault path, to its plaintext value. Providers are registered with
+// RegisterSecretProvider and selected per secret variable via WithSecretVar's
+// providerName argument.
+type SecretProvider interface {
+	Decrypt(ref string) (string, error)
+}
+
+var secretProviders = map[string]SecretProvider{}
+
+// flagProviders maps an encrypted flag's name to the SecretProvider it was
+// registered with via WithSecretVar.
+var flagProviders = map[string]string{}
+
+func init() {
+	RegisterSecretProvider(gpgFileProviderName, gpgFileProvider{})
+	RegisterSecretProvider("symmetric", symmetricProvider{})
+	RegisterSecretProvider("sops", unimplementedProvider("sops"))
+	RegisterSecretProvider("aws-kms", unimplementedProvider("aws-kms"))
+	RegisterSecretProvider("gcp-kms", unimplementedProvider("gcp-kms"))
+	RegisterSecretProvider("vault", unimplementedProvider("vault"))
+}
+
+// RegisterSecretProvider makes provider available under name for use with
+// WithSecretVar. Registering under an existing name replaces it, so callers
+// can override a built-in provider (e.g. "sops") with a real implementation.
+func RegisterSecretProvider(name string, provider SecretProvider) {
+	secretProviders[name] = provider
+}
+
+// WithSecretVar registers a flag whose value is a reference, such as a file
+// path, KMS key ID, or Vault path, resolved to a plaintext secret by the
+// named SecretProvider at InitConfig time. It allows this package to present
+// its unique way of setting program arguments to the user while still
+// leaning on spf13/pflag for flag setting logic. This function uses a
+// separate pflag.FlagSet that is used to identify encrypted values.
+//
+// If providerName is one of the unimplemented stubs ("sops", "aws-kms",
+// "gcp-kms", "vault") or isn't registered at all (e.g. a typo), that's a
+// misconfiguration InitConfig reports as an error rather than silently leaving opt empty; see
+// unimplementedProvider and decryptEncryptedValues.
+func WithSecretVar(opt *string, name string, defaultRef string, usage string, providerName string) ProgramOpts {
+	return func() {
+		encrypted.StringVar(opt, name, defaultRef, usage)
+		flagProviders[name] = providerName
+	}
+}
+
+// secretProviderFor returns the SecretProvider registered for flag name,
+// falling back to the gpg-file provider for flags set up before providers
+// existed, and to unimplementedProvider if the name isn't registered.
+func secretProviderFor(name string) SecretProvider {
+	providerName, ok := flagProviders[name]
+	if !ok || providerName == "" {
+		providerName = gpgFileProviderName
+	}
+	provider, ok := secretProviders[providerName]
+	if !ok {
+		return unimplementedProvider(providerName)
+	}
+	return provider
+}
+
+// gpgFileProvider decrypts a local GPG-encrypted file using the keyring
+// loaded from GnuPGHome. It is the original, and still default, easycfg
+// secret backend.
+type gpgFileProvider struct{}
+
+func (gpgFileProvider) Decrypt(ref string) (string, error) {
+	return gpgDecodeFromFile(ref)
+}
+
+// symmetricProvider decrypts a file encrypted with openpgp.SymmetricallyEncrypt,
+// using a passphrase read from symmetricPassphraseEnvVar. This mirrors the
+// symmetric-encryption path used by tools like docker-volume-backup, for
+// environments where a per-host gnupg keyring is not practical.
+type symmetricProvider struct{}
+
+func (symmetricProvider) Decrypt(ref string) (string, error) {
+	pass, ok := os.LookupEnv(symmetricPassphraseEnvVar)
+	if !ok {
+		return "", fmt.Errorf("easycfg: %s not set", symmetricPassphraseEnvVar)
+	}
+
+	f, err := os.Open(ref)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	md, err := openpgp.ReadMessage(f, nil, func(keys []openpgp.Key, symmetric bool) ([]byte, error) {
+		return []byte(pass), nil
+	}, &packet.Config{})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return "", err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	return string(data), nil
+}
+
+// unimplementedProvider is a placeholder SecretProvider for backends that are
+// not yet wired up. Callers who need one of these today should register a
+// real implementation with RegisterSecretProvider under the same name.
+type unimplementedProvider string
+
+func (p unimplementedProvider) Decrypt(ref string) (string, error) {
+	return "", fmt.Errorf("easycfg: secret provider %q is not yet implemented", string(p))
+}
@@ -0,0 +1,152 @@
+package easycfg
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// fieldBinding assigns viper's current value for name into a single
+// RegisterStruct field, applied by applyStructBindings once InitConfig has
+// parsed flags, read configuration, and decrypted secrets.
+type fieldBinding struct {
+	name  string
+	apply func()
+}
+
+// easycfgTag is the parsed form of an `easycfg:"..."` struct tag, e.g.
+// `easycfg:"addr,default=:8080,usage=listen address"`.
+type easycfgTag struct {
+	name   string
+	def    string
+	usage  string
+	secret string
+}
+
+// parseEasycfgTag parses the comma-separated value of an `easycfg` struct
+// tag. The first segment is the flag name; remaining segments are
+// key=value pairs (default, usage, secret). A "-" name, like encoding/json,
+// opts the field out.
+func parseEasycfgTag(raw string) (easycfgTag, bool) {
+	parts := strings.Split(raw, ",")
+	if len(parts) == 0 || parts[0] == "" || parts[0] == "-" {
+		return easycfgTag{}, false
+	}
+
+	tag := easycfgTag{name: parts[0]}
+	for _, part := range parts[1:] {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "default":
+			tag.def = kv[1]
+		case "usage":
+			tag.usage = kv[1]
+		case "secret":
+			tag.secret = kv[1]
+		}
+	}
+	return tag, true
+}
+
+// RegisterStruct reflects over cfg, a pointer to a struct, and auto-registers
+// flags, env bindings, defaults, and secret references from its `easycfg`
+// struct tags, e.g.:
+//
+//	type Cfg struct {
+//		Addr   string `easycfg:"addr,default=:8080,usage=listen address"`
+//		DBPass string `easycfg:"db_pass,secret=gpg-file,usage=encrypted db password"`
+//	}
+//
+// Once InitConfig has parsed flags, read configuration, and decrypted
+// secrets, cfg's fields are populated by looking up each field's `easycfg`
+// tag name in viper directly (see applyStructBindings) rather than via
+// viper.Unmarshal, whose mapstructure decoder only matches its own
+// `mapstructure` tag or a case-insensitive field name and would silently
+// ignore a tag name like "db_pass" on a field named DBPass. This removes the
+// one-call-per-field ceremony of WithStringVar/WithIntVar/WithSecretVar for
+// callers who would rather describe their config as a struct.
+func RegisterStruct(cfg interface{}) error {
+	ensureInitialized()
+
+	v := reflect.ValueOf(cfg)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("easycfg: RegisterStruct requires a non-nil pointer to a struct")
+	}
+	v = v.Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		raw, ok := field.Tag.Lookup("easycfg")
+		if !ok {
+			continue
+		}
+		tag, ok := parseEasycfgTag(raw)
+		if !ok {
+			continue
+		}
+
+		fv := v.Field(i)
+		if !fv.CanAddr() || !fv.CanSet() {
+			return fmt.Errorf("easycfg: field %s is not settable", field.Name)
+		}
+
+		if tag.secret != "" {
+			opt, ok := fv.Addr().Interface().(*string)
+			if !ok {
+				return fmt.Errorf("easycfg: field %s tagged as a secret must be a string", field.Name)
+			}
+			WithSecretVar(opt, tag.name, tag.def, tag.usage, tag.secret)()
+			structBindings = append(structBindings, fieldBinding{
+				name:  tag.name,
+				apply: func() { fv.SetString(viper.GetString(tag.name)) },
+			})
+			continue
+		}
+
+		switch opt := fv.Addr().Interface().(type) {
+		case *string:
+			WithStringVar(opt, tag.name, tag.def, tag.usage)()
+			structBindings = append(structBindings, fieldBinding{
+				name:  tag.name,
+				apply: func() { fv.SetString(viper.GetString(tag.name)) },
+			})
+		case *int:
+			def, _ := strconv.Atoi(tag.def)
+			WithIntVar(opt, tag.name, def, tag.usage)()
+			structBindings = append(structBindings, fieldBinding{
+				name:  tag.name,
+				apply: func() { fv.SetInt(int64(viper.GetInt(tag.name))) },
+			})
+		case *bool:
+			def, _ := strconv.ParseBool(tag.def)
+			WithBoolVar(opt, tag.name, def, tag.usage)()
+			structBindings = append(structBindings, fieldBinding{
+				name:  tag.name,
+				apply: func() { fv.SetBool(viper.GetBool(tag.name)) },
+			})
+		default:
+			return fmt.Errorf("easycfg: field %s has unsupported type %s", field.Name, field.Type)
+		}
+	}
+
+	return nil
+}
+
+// applyStructBindings assigns viper's current value into every field
+// registered via RegisterStruct, keyed by the field's `easycfg` tag name. It
+// runs at the end of InitConfig, after flags are parsed, configuration is
+// read, and secrets are decrypted, so struct fields see the same
+// flag > env > config file > default precedence as everything else.
+func applyStructBindings() error {
+	for _, binding := range structBindings {
+		binding.apply()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package easycfg
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestParseEasycfgTag(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want easycfgTag
+		ok   bool
+	}{
+		{
+			name: "name, default, and usage",
+			raw:  "addr,default=:8080,usage=listen address",
+			want: easycfgTag{name: "addr", def: ":8080", usage: "listen address"},
+			ok:   true,
+		},
+		{
+			name: "secret provider",
+			raw:  "db_pass,secret=gpg-file,usage=encrypted db password",
+			want: easycfgTag{name: "db_pass", secret: "gpg-file", usage: "encrypted db password"},
+			ok:   true,
+		},
+		{name: "opt out", raw: "-", ok: false},
+		{name: "empty", raw: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseEasycfgTag(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRegisterStructBindsByTagName guards against the bug where
+// viper.Unmarshal was used to populate RegisterStruct fields: mapstructure
+// matches by its own tag or a case-insensitive field name, so a field like
+// DBPass tagged `easycfg:"db_pass,..."` was never populated by a value set
+// under the "db_pass" key. applyStructBindings must look the value up by
+// the easycfg tag name instead.
+func TestRegisterStructBindsByTagName(t *testing.T) {
+	type cfg struct {
+		Addr   string `easycfg:"ecfgtest_addr,default=:8080,usage=listen address"`
+		DBPass string `easycfg:"ecfgtest_db_pass,secret=gpg-file,usage=encrypted db password"`
+	}
+
+	var c cfg
+	if err := RegisterStruct(&c); err != nil {
+		t.Fatalf("RegisterStruct: %v", err)
+	}
+
+	// Stand in for InitConfig having resolved env/config/decrypted-secret
+	// values into viper under the tag names, which needn't case-fold to the
+	// Go field names (the motivating example: DBPass vs. "db_pass").
+	viper.Set("ecfgtest_addr", ":9999")
+	viper.Set("ecfgtest_db_pass", "fromenv2")
+
+	if err := applyStructBindings(); err != nil {
+		t.Fatalf("applyStructBindings: %v", err)
+	}
+
+	if c.Addr != ":9999" {
+		t.Errorf("Addr = %q, want %q", c.Addr, ":9999")
+	}
+	if c.DBPass != "fromenv2" {
+		t.Errorf("DBPass = %q, want %q", c.DBPass, "fromenv2")
+	}
+}